@@ -5,41 +5,46 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
+	"github.com/sicashproject/solar/compiler"
 	"github.com/sicashproject/solar/contract"
 	"github.com/sicashproject/solar/deployer"
 	"github.com/sicashproject/solar/deployer/eth"
 	"github.com/sicashproject/solar/deployer/sicash"
+	"github.com/sicashproject/solar/keystore"
 	"github.com/sicashproject/solar/varstr"
-	kingpin "gopkg.in/alecthomas/kingpin.v2"
-)
-
-var (
-	app               = kingpin.New("solar", "Solidity smart contract deployment management.")
-	sicashRPC           = app.Flag("sicash_rpc", "RPC provider url").Envar("SICASH_RPC").String()
-	sicashSenderAddress = app.Flag("sicash_sender", "(sicash) Sender UTXO Address").Envar("SICASH_SENDER").String()
-
-	// geth --rpc --rpcapi="eth,personal,miner"
-	ethRPC    = app.Flag("eth_rpc", "RPC provider url").Envar("ETH_RPC").String()
-	solarEnv  = app.Flag("env", "Environment name").Envar("SOLAR_ENV").Default("development").String()
-	solarRepo = app.Flag("repo", "Path of contracts repository").Envar("SOLAR_REPO").String()
-	appTasks  = map[string]func() error{}
-
-	solcOptimize   = app.Flag("optimize", "[solc] should Enable bytecode optimizer").Default("true").Bool()
-	solcAllowPaths = app.Flag("allow-paths", "[solc] Allow a given path for imports. A list of paths can be supplied by separating them with a comma.").Default("").String()
+	"github.com/urfave/cli/v2"
 )
 
 type RPCPlatform int
 
 const (
-	RPCSICash     = iota
+	RPCSICash   = iota
 	RPCEthereum = iota
 )
 
+// solarCLI holds both the parsed flag values and the lazily-built deployer,
+// contracts repository and event reporter built from them. Every `cli.Command`
+// declares its own flags (built fresh by rpcFlags() and friends), and parsing
+// them writes the relevant fields of the shared `solar` value via Destination.
 type solarCLI struct {
+	sicashRPC           string
+	sicashSenderAddress string
+	ethRPC              string
+	chainIDs            string
+	solarEnv            string
+	solarRepo           string
+	solcOptimize        bool
+	solcAllowPaths      string
+
+	keystoreDir             string
+	keystorePassphraseFile  string
+	keystoreContractSenders string
+
 	depoyer      deployer.Deployer
 	deployerOnce sync.Once
 
@@ -50,18 +55,79 @@ type solarCLI struct {
 	reporterOnce sync.Once
 }
 
-var solar = &solarCLI{}
+var solar = &solarCLI{
+	solarEnv:     "development",
+	solcOptimize: true,
+}
 
 var (
 	errorUnspecifiedRPC = errors.New("Please specify RPC url by setting SICASH_RPC or ETH_RPC or using flag --sicash_rpc or --eth_rpc")
 )
 
+// rpcFlags are shared by every command that talks to a chain: deploy, status,
+// verify and serve all need to know where to find it.
+func rpcFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "sicash_rpc", Usage: "RPC provider url. A list of urls can be supplied by separating them with a comma, to fan a single deploy out to every endpoint", EnvVars: []string{"SICASH_RPC"}, Destination: &solar.sicashRPC},
+		&cli.StringFlag{Name: "sicash_sender", Usage: "(sicash) Sender UTXO Address", EnvVars: []string{"SICASH_SENDER"}, Destination: &solar.sicashSenderAddress},
+		// geth --rpc --rpcapi="eth,personal,miner"
+		&cli.StringFlag{Name: "eth_rpc", Usage: "RPC provider url. A list of urls can be supplied by separating them with a comma, to fan a single deploy out to every endpoint", EnvVars: []string{"ETH_RPC"}, Destination: &solar.ethRPC},
+		&cli.StringFlag{Name: "chain_ids", Usage: "Chain ids matching --sicash_rpc/--eth_rpc, in order (sicash urls first, then eth urls). A list can be supplied by separating them with a comma", EnvVars: []string{"SOLAR_CHAIN_IDS"}, Destination: &solar.chainIDs},
+	}
+}
+
+// ethFlags let solar sign and submit deploy transactions locally instead of
+// relying on a remote node's personal_* RPC namespace.
+func ethFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "keystore", Usage: "(eth) Directory of scrypt-encrypted JSON keyfiles to sign deploy transactions with locally", EnvVars: []string{"SOLAR_KEYSTORE"}, Destination: &solar.keystoreDir},
+		&cli.StringFlag{Name: "keystore_passphrase_file", Usage: "(eth) File holding the passphrase used to unlock every key in --keystore", EnvVars: []string{"SOLAR_KEYSTORE_PASSPHRASE_FILE"}, Destination: &solar.keystorePassphraseFile},
+		&cli.StringFlag{Name: "keystore_senders", Usage: "(eth) Comma-separated ContractName=0xAddress pairs pinning a contract's deploy to a specific unlocked key, overriding round-robin selection", EnvVars: []string{"SOLAR_KEYSTORE_SENDERS"}, Destination: &solar.keystoreContractSenders},
+	}
+}
+
+func repoFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "env", Usage: "Environment name", EnvVars: []string{"SOLAR_ENV"}, Value: "development", Destination: &solar.solarEnv},
+		&cli.StringFlag{Name: "repo", Usage: "Path of contracts repository", EnvVars: []string{"SOLAR_REPO"}, Destination: &solar.solarRepo},
+	}
+}
+
+func solcFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: "optimize", Usage: "[solc] should Enable bytecode optimizer", Value: true, Destination: &solar.solcOptimize},
+		&cli.StringFlag{Name: "allow-paths", Usage: "[solc] Allow a given path for imports. A list of paths can be supplied by separating them with a comma.", Destination: &solar.solcAllowPaths},
+	}
+}
+
+var app = &cli.App{
+	Name:                 "solar",
+	Usage:                "Solidity smart contract deployment management.",
+	EnableBashCompletion: true,
+	Commands: []*cli.Command{
+		contractCommand,
+		repoCommand,
+		keysCommand,
+		serveCommand,
+		verifyCommand,
+		completionCommand,
+	},
+}
+
+// rpcBefore is the cli.Command.Before for every command that talks to a chain.
+// It can only run once rpcFlags() have been parsed for that specific command,
+// which is why it isn't hung off the app itself.
+func rpcBefore(c *cli.Context) error {
+	solar.ConfigureBytesOutputFormat()
+	return nil
+}
+
 func (c *solarCLI) RPCPlatform() RPCPlatform {
-	if *sicashRPC == "" && *ethRPC == "" {
+	if c.sicashRPC == "" && c.ethRPC == "" {
 		log.Fatalln(errorUnspecifiedRPC)
 	}
 
-	if *sicashRPC != "" {
+	if c.sicashRPC != "" {
 		return RPCSICash
 	}
 
@@ -80,8 +146,8 @@ func (c *solarCLI) Reporter() *events {
 	return c.reporter
 }
 
-func (c *solarCLI) SolcOptions() (*CompilerOptions, error) {
-	allowPathsStr := *solcAllowPaths
+func (c *solarCLI) SolcOptions() (*compiler.Options, error) {
+	allowPathsStr := c.solcAllowPaths
 	if allowPathsStr == "" {
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -93,8 +159,8 @@ func (c *solarCLI) SolcOptions() (*CompilerOptions, error) {
 
 	allowPaths := strings.Split(allowPathsStr, ",")
 
-	return &CompilerOptions{
-		NoOptimize: !*solcOptimize,
+	return &compiler.Options{
+		NoOptimize: !c.solcOptimize,
 		AllowPaths: allowPaths,
 	}, nil
 }
@@ -102,29 +168,57 @@ func (c *solarCLI) SolcOptions() (*CompilerOptions, error) {
 // Open the file `solar.{SOLAR_ENV}.json` as contracts repository
 func (c *solarCLI) ContractsRepository() *contract.ContractsRepository {
 	c.repoOnce.Do(func() {
-		var repoFilePath string
-		if *solarRepo != "" {
-			repoFilePath = *solarRepo
-		} else {
-			repoFilePath = fmt.Sprintf("solar.%s.json", *solarEnv)
-		}
-
-		repo, err := contract.OpenContractsRepository(repoFilePath)
-		if err != nil {
-			fmt.Printf("error opening contracts repo file %s: %s\n", repoFilePath, err)
-			os.Exit(1)
-		}
-
-		c.repo = repo
+		c.repo = c.openContractsRepository(c.solarRepo)
 	})
 
 	return c.repo
 }
 
+// ContractsRepositoryForChain opens the contracts repository scoped to a single
+// chain id, so a multi-chain deploy can record each chain's deployed addresses
+// separately: `solar.{SOLAR_ENV}.{chainID}.json`. With a single configured chain
+// (chainID == "") this is equivalent to ContractsRepository. When --repo picks a
+// custom path, chainID is folded into that path too, so an explicit --repo
+// doesn't put every chain target back on the same file (the race a97e7e6 added
+// --chain_ids validation to prevent).
+func (c *solarCLI) ContractsRepositoryForChain(chainID string) *contract.ContractsRepository {
+	if chainID == "" {
+		return c.ContractsRepository()
+	}
+
+	repoFilePath := fmt.Sprintf("solar.%s.%s.json", c.solarEnv, chainID)
+	if c.solarRepo != "" {
+		repoFilePath = withChainSuffix(c.solarRepo, chainID)
+	}
+
+	return c.openContractsRepository(repoFilePath)
+}
+
+// withChainSuffix inserts chainID just before path's extension, e.g.
+// withChainSuffix("solar.json", "1") -> "solar.1.json".
+func withChainSuffix(path, chainID string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + chainID + ext
+}
+
+func (c *solarCLI) openContractsRepository(repoFilePath string) *contract.ContractsRepository {
+	if repoFilePath == "" {
+		repoFilePath = fmt.Sprintf("solar.%s.json", c.solarEnv)
+	}
+
+	repo, err := contract.OpenContractsRepository(repoFilePath)
+	if err != nil {
+		fmt.Printf("error opening contracts repo file %s: %s\n", repoFilePath, err)
+		os.Exit(1)
+	}
+
+	return repo
+}
+
 func (c *solarCLI) SICashRPC() *sicash.RPC {
-	rpc, err := sicash.NewRPC(*sicashRPC)
+	rpc, err := sicash.NewRPC(c.sicashRPC)
 	if err != nil {
-		fmt.Println("Invalid SICASH RPC URL:", *sicashRPC)
+		fmt.Println("Invalid SICASH RPC URL:", c.sicashRPC)
 		os.Exit(1)
 	}
 
@@ -146,58 +240,141 @@ func (c *solarCLI) ExpandJSONParams(jsonParams string) string {
 }
 
 func (c *solarCLI) ConfigureBytesOutputFormat() {
-	if *ethRPC != "" {
+	if c.ethRPC != "" {
 		contract.SetFormatBytesWithPrefix(true)
 	}
 }
 
-func (c *solarCLI) Deployer() (deployer deployer.Deployer) {
+// splitEndpoints splits a comma-separated flag value into its endpoints, dropping
+// surrounding whitespace and empty entries.
+func splitEndpoints(csv string) []string {
+	if csv == "" {
+		return nil
+	}
+
+	var endpoints []string
+	for _, endpoint := range strings.Split(csv, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return endpoints
+}
+
+// keyStore builds the local signer configured via --keystore, or returns nil if
+// none was configured, in which case the eth deployer falls back to personal_*
+// on the remote node as before. Every key is unlocked before --keystore_senders
+// is applied, either in one shot via --keystore_passphrase_file or, if that's
+// not given, by prompting for each key's passphrase interactively.
+func (c *solarCLI) keyStore() (*keystore.KeyStore, error) {
+	if c.keystoreDir == "" {
+		return nil, nil
+	}
+
+	ks := keystore.Open(c.keystoreDir)
+
+	if c.keystorePassphraseFile != "" {
+		if err := ks.UnlockWithPassphraseFile(c.keystorePassphraseFile); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := promptUnlockKeystore(ks); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pair := range splitEndpoints(c.keystoreContractSenders) {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid --keystore_senders entry %q, expected ContractName=0xAddress", pair)
+		}
+
+		if err := ks.AssignToContract(parts[0], parts[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+func (c *solarCLI) Deployer() deployer.Deployer {
 	log := log.New(os.Stderr, "", log.Lshortfile)
 
-	var err error
-	var rpcURL *url.URL
+	sicashURLs := splitEndpoints(c.sicashRPC)
+	ethURLs := splitEndpoints(c.ethRPC)
+
+	if len(sicashURLs) == 0 && len(ethURLs) == 0 {
+		log.Fatalln(errorUnspecifiedRPC)
+	}
+
+	ids := splitEndpoints(c.chainIDs)
+	total := len(sicashURLs) + len(ethURLs)
 
-	if rawurl := *sicashRPC; rawurl != "" {
+	if total > 1 && len(ids) < total {
+		log.Fatalf("--chain_ids must list one chain id per --sicash_rpc/--eth_rpc endpoint (got %d endpoint(s), %d chain id(s)): deploying to multiple chains without one repository per chain would race on the same contracts repository file", total, len(ids))
+	}
 
-		rpcURL, err = url.ParseRequestURI(rawurl)
-		if err != nil {
-			log.Fatalf("Invalid RPC url: %#v", rawurl)
+	var targets []chainTarget
+	idAt := func(i int) string {
+		if i < len(ids) {
+			return ids[i]
 		}
-		deployer, err = sicash.NewDeployer(rpcURL, c.ContractsRepository(), *sicashSenderAddress)
+		return ""
 	}
 
-	if rawurl := *ethRPC; rawurl != "" {
-		rpcURL, err = url.ParseRequestURI(rawurl)
+	for i, rawurl := range sicashURLs {
+		chainID := idAt(i)
+
+		rpcURL, err := url.ParseRequestURI(rawurl)
 		if err != nil {
 			log.Fatalf("Invalid RPC url: %#v", rawurl)
 		}
 
-		deployer, err = eth.NewDeployer(rpcURL, c.ContractsRepository())
-	}
+		d, err := sicash.NewDeployer(rpcURL, c.ContractsRepositoryForChain(chainID), c.sicashSenderAddress)
+		if err != nil {
+			log.Fatalf("NewDeployer error %v", err)
+		}
 
-	if deployer == nil {
-		log.Fatalln(errorUnspecifiedRPC)
+		targets = append(targets, chainTarget{chainID: chainID, deployer: d})
 	}
 
+	ks, err := c.keyStore()
 	if err != nil {
-		log.Fatalf("NewDeployer error %v", err)
+		log.Fatalf("keystore error %v", err)
 	}
 
-	return deployer
-}
+	for i, rawurl := range ethURLs {
+		chainID := idAt(len(sicashURLs) + i)
 
-func Main() {
-	cmdName, err := app.Parse(os.Args[1:])
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		rpcURL, err := url.ParseRequestURI(rawurl)
+		if err != nil {
+			log.Fatalf("Invalid RPC url: %#v", rawurl)
+		}
+
+		var d deployer.Deployer
+		if ks != nil {
+			d, err = eth.NewDeployerWithKeystore(rpcURL, c.ContractsRepositoryForChain(chainID), ks)
+		} else {
+			d, err = eth.NewDeployer(rpcURL, c.ContractsRepositoryForChain(chainID))
+		}
+		if err != nil {
+			log.Fatalf("NewDeployer error %v", err)
+		}
+
+		targets = append(targets, chainTarget{chainID: chainID, deployer: d})
 	}
 
-	solar.ConfigureBytesOutputFormat()
+	if len(targets) == 1 {
+		return targets[0].deployer
+	}
 
-	task := appTasks[cmdName]
-	err = task()
-	if err != nil {
+	return &multiDeployer{targets: targets}
+}
+
+func Main() {
+	if err := app.Run(os.Args); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -0,0 +1,61 @@
+package solar
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// completionCommand prints a shell init script that wires up
+// --generate-bash-completion-style completion for bash, zsh or fish, e.g.:
+//
+//	eval "$(solar completion bash)"
+var completionCommand = &cli.Command{
+	Name:      "completion",
+	Usage:     "Print a shell completion script",
+	ArgsUsage: "<bash|zsh|fish>",
+	Action: func(c *cli.Context) error {
+		shell := c.Args().First()
+
+		switch shell {
+		case "bash":
+			fmt.Print(bashCompletionScript)
+		case "zsh":
+			fmt.Print(zshCompletionScript)
+		case "fish":
+			fmt.Print(fishCompletionScript)
+		default:
+			return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", shell)
+		}
+
+		return nil
+	},
+}
+
+const bashCompletionScript = `#! /bin/bash
+_solar_bash_autocomplete() {
+  local cur opts base
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  opts=$( "${COMP_WORDS[0]}" "${COMP_WORDS[@]:1:$COMP_CWORD-1}" --generate-bash-completion )
+  COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
+  return 0
+}
+complete -o bashdefault -o default -F _solar_bash_autocomplete solar
+`
+
+const zshCompletionScript = `#compdef solar
+_solar() {
+  local -a opts
+  opts=("${(@f)$(${words[1]} ${words[2,-2]} --generate-bash-completion)}")
+  _describe 'command' opts
+}
+compdef _solar solar
+`
+
+const fishCompletionScript = `function __solar_complete
+  set -lx COMP_LINE (commandline -cp)
+  solar --generate-bash-completion
+end
+complete -c solar -f -a "(__solar_complete)"
+`
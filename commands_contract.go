@@ -0,0 +1,38 @@
+package solar
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// contractCommand groups every subcommand that inspects or mutates the set of
+// deployed contracts: `solar contract deploy`, `solar contract status`.
+var contractCommand = &cli.Command{
+	Name:  "contract",
+	Usage: "Deploy and inspect contracts",
+	Subcommands: []*cli.Command{
+		{
+			Name:        "deploy",
+			Usage:       "Deploy the contracts declared in solar.json",
+			Description: deployCommandDescription(),
+			Flags:       append(rpcFlags(), append(repoFlags(), append(solcFlags(), ethFlags()...)...)...),
+			Before:      rpcBefore,
+			Action: func(c *cli.Context) error {
+				return solar.Deployer().Deploy()
+			},
+		},
+		{
+			Name:   "status",
+			Usage:  "List the contracts recorded in the contracts repository",
+			Flags:  repoFlags(),
+			Before: rpcBefore,
+			Action: func(c *cli.Context) error {
+				for name := range solar.ContractsRepository().ListAll() {
+					fmt.Println(name)
+				}
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,32 @@
+package solar
+
+import (
+	"fmt"
+
+	"github.com/sicashproject/solar/keystore"
+	"github.com/urfave/cli/v2"
+)
+
+var keysDir string
+
+// keysCommand groups subcommands for managing the local signing keys solar
+// deploys with, see keystore/keystore.go.
+var keysCommand = &cli.Command{
+	Name:  "keys",
+	Usage: "Manage local signing keys",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List every keyfile found in a keystore directory",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "keystore", Usage: "Directory of scrypt-encrypted JSON keyfiles", EnvVars: []string{"SOLAR_KEYSTORE"}, Required: true, Destination: &keysDir},
+			},
+			Action: func(c *cli.Context) error {
+				for _, account := range keystore.Open(keysDir).Accounts() {
+					fmt.Println(account.Address.Hex())
+				}
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,33 @@
+package solar
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// repoCommand groups subcommands that operate on the contracts repository file
+// itself, as opposed to the chain: `solar repo prune`.
+var repoCommand = &cli.Command{
+	Name:  "repo",
+	Usage: "Manage the contracts repository file",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "prune",
+			Usage: "Remove repository entries that no longer appear in any local source file",
+			Flags: repoFlags(),
+			Action: func(c *cli.Context) error {
+				removed, err := solar.ContractsRepository().Prune()
+				if err != nil {
+					return err
+				}
+
+				for _, name := range removed {
+					fmt.Println("removed:", name)
+				}
+
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,24 @@
+package solar
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var serveAddresses string
+
+// serveCommand runs solar as a long-running JSON-RPC service, see rpcservice.go.
+var serveCommand = &cli.Command{
+	Name:  "serve",
+	Usage: "Run solar as a long-running JSON-RPC service",
+	Flags: append(rpcFlags(), append(repoFlags(), append(solcFlags(), append(ethFlags(), &cli.StringFlag{
+		Name:        "addresses",
+		Usage:       "host:port (or unix socket path) to listen on. A list can be supplied by separating them with a comma.",
+		EnvVars:     []string{"SOLAR_SERVE_ADDRESSES"},
+		Value:       "localhost:9090",
+		Destination: &serveAddresses,
+	})...)...)...),
+	Before: rpcBefore,
+	Action: func(c *cli.Context) error {
+		return newRPCService(solar).Serve(splitEndpoints(serveAddresses))
+	},
+}
@@ -0,0 +1,49 @@
+package solar
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	verifyChainID string
+	verifySources string
+)
+
+// verifyCommand re-fetches a contract's deployed bytecode and compares it
+// against a local build compiled fresh from --source, see verify.go.
+var verifyCommand = &cli.Command{
+	Name:      "verify",
+	Usage:     "Compare a contract's deployed bytecode against the local build",
+	ArgsUsage: "<name>",
+	Flags: append(rpcFlags(), append(repoFlags(), append(solcFlags(), &cli.StringFlag{
+		Name:        "chain_id",
+		Usage:       "Chain id to verify against, matching one of the ids passed to --chain_ids. Required when more than one --sicash_rpc/--eth_rpc endpoint is configured",
+		Destination: &verifyChainID,
+	}, &cli.StringFlag{
+		Name:        "source",
+		Usage:       "Comma-separated .sol file(s) to compile <name> from, to build the local baseline it's compared against",
+		Destination: &verifySources,
+	})...)...),
+	Before: rpcBefore,
+	Action: func(c *cli.Context) error {
+		name := c.Args().First()
+		if name == "" {
+			return fmt.Errorf("usage: solar verify <name>")
+		}
+
+		result, err := solar.Verify(name, verifyChainID, splitEndpoints(verifySources))
+		if err != nil {
+			return err
+		}
+
+		if result.Match {
+			fmt.Printf("%s: OK, deployed bytecode matches\n", result.Name)
+			return nil
+		}
+
+		fmt.Printf("%s: MISMATCH\n  expected: %s\n  actual:   %s\n", result.Name, result.Expected, result.Actual)
+		return fmt.Errorf("%s: deployed bytecode drifted from the local build", result.Name)
+	},
+}
@@ -0,0 +1,223 @@
+// Package compiler wraps the solc binary, compiling one or more Solidity source
+// files via `solc --combined-json` and parsing the result into Contract values
+// that carry ABI, bytecode and NatSpec metadata alongside the rest of the output.
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// combinedJSONFields is the set of solc --combined-json outputs we need to build a
+// Contract: bytecode, deployed bytecode, ABI and the NatSpec doc blobs.
+const combinedJSONFields = "bin,bin-runtime,abi,userdoc,devdoc,metadata"
+
+// Options configures a solc invocation.
+type Options struct {
+	// NoOptimize disables the solc bytecode optimizer.
+	NoOptimize bool
+
+	// AllowPaths is passed to solc as --allow-paths, so imports outside of the
+	// source directory (e.g. node_modules-style libraries) can be resolved.
+	AllowPaths []string
+}
+
+// Contract is a single compiled contract, combining everything solc reports about
+// it in one place: ABI, bytecode, deployed bytecode, metadata and NatSpec
+// documentation. Source mappings are not captured.
+type Contract struct {
+	ABI         json.RawMessage `json:"abi"`
+	Bin         string          `json:"bin"`
+	DeployedBin string          `json:"bin-runtime"`
+	Metadata    string          `json:"metadata"`
+	Userdoc     json.RawMessage `json:"userdoc"`
+	Devdoc      json.RawMessage `json:"devdoc"`
+}
+
+// combinedJSON mirrors the shape of `solc --combined-json`'s stdout closely enough
+// to unmarshal it; solc nests each contract's fields under raw strings rather than
+// a properly typed object, so the JSON-valued ones (abi, userdoc, devdoc) are
+// kept as raw JSON below rather than decoded further.
+type combinedJSON struct {
+	Contracts map[string]struct {
+		Bin        string `json:"bin"`
+		BinRuntime string `json:"bin-runtime"`
+		Abi        string `json:"abi"`
+		Userdoc    string `json:"userdoc"`
+		Devdoc     string `json:"devdoc"`
+		Metadata   string `json:"metadata"`
+	} `json:"contracts"`
+	Version string `json:"version"`
+}
+
+var (
+	cache   = map[string]map[string]*Contract{}
+	cacheMu sync.Mutex
+)
+
+// Compile runs solc once over the given source files and returns every contract
+// it finds, keyed the same way solc keys them (`path/to/File.sol:ContractName`).
+// Results are cached by (source contents, solc version, optimizer settings,
+// allow-paths), so re-compiling an unchanged set of sources is free.
+func Compile(opts Options, files ...string) (map[string]*Contract, error) {
+	if len(files) == 0 {
+		return nil, errors.New("compiler: no source files given")
+	}
+
+	version, err := solcVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "solc version")
+	}
+
+	key, err := cacheKey(opts, version, files)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiler cache key")
+	}
+
+	cacheMu.Lock()
+	cached, found := cache[key]
+	cacheMu.Unlock()
+	if found {
+		return cached, nil
+	}
+
+	// solc resolves relative imports against the working directory it is run
+	// from, which trips over sources that live in different directories. Copy
+	// everything into one temp dir first so imports always resolve the same way.
+	srcDir, staged, err := copySourcesToTempDir(files)
+	if err != nil {
+		return nil, errors.Wrap(err, "staging sources")
+	}
+	defer os.RemoveAll(srcDir)
+
+	args := []string{"--combined-json", combinedJSONFields}
+	if !opts.NoOptimize {
+		args = append(args, "--optimize")
+	}
+	if len(opts.AllowPaths) > 0 {
+		args = append(args, "--allow-paths", strings.Join(opts.AllowPaths, ","))
+	}
+	for _, file := range files {
+		args = append(args, staged[file])
+	}
+
+	cmd := exec.Command("solc", args...)
+	cmd.Dir = srcDir
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, errors.Errorf("solc: %s", string(exitErr.Stderr))
+		}
+		return nil, errors.Wrap(err, "running solc")
+	}
+
+	var parsed combinedJSON
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, errors.Wrap(err, "parsing solc output")
+	}
+
+	contracts := make(map[string]*Contract, len(parsed.Contracts))
+	for name, raw := range parsed.Contracts {
+		contracts[name] = &Contract{
+			ABI:         json.RawMessage(raw.Abi),
+			Bin:         raw.Bin,
+			DeployedBin: raw.BinRuntime,
+			Metadata:    raw.Metadata,
+			Userdoc:     json.RawMessage(raw.Userdoc),
+			Devdoc:      json.RawMessage(raw.Devdoc),
+		}
+	}
+
+	cacheMu.Lock()
+	cache[key] = contracts
+	cacheMu.Unlock()
+
+	return contracts, nil
+}
+
+func solcVersion() (string, error) {
+	out, err := exec.Command("solc", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// copySourcesToTempDir stages every file under dir, mirroring each one's
+// original absolute path rather than flattening to its basename, so two
+// inputs that happen to share a basename but live in different directories
+// (e.g. vendored copies of a library like SafeMath.sol) don't overwrite each
+// other. It returns dir and a file -> staged path lookup.
+func copySourcesToTempDir(files []string) (string, map[string]string, error) {
+	dir, err := ioutil.TempDir("", "solar-solc")
+	if err != nil {
+		return "", nil, err
+	}
+
+	staged := make(map[string]string, len(files))
+
+	for _, file := range files {
+		absFile, err := filepath.Abs(file)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", nil, errors.Wrapf(err, "resolving %s", file)
+		}
+
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", nil, errors.Wrapf(err, "reading %s", file)
+		}
+
+		dst := filepath.Join(dir, absFile)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			os.RemoveAll(dir)
+			return "", nil, errors.Wrapf(err, "staging %s", file)
+		}
+
+		if err := ioutil.WriteFile(dst, content, 0644); err != nil {
+			os.RemoveAll(dir)
+			return "", nil, errors.Wrapf(err, "staging %s", file)
+		}
+
+		staged[file] = dst
+	}
+
+	return dir, staged, nil
+}
+
+// cacheKey hashes everything a compilation's output depends on: the contents of
+// every source file, the solc version, and the optimizer/allow-paths settings.
+func cacheKey(opts Options, solcVersion string, files []string) (string, error) {
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	fmt.Fprintln(h, solcVersion)
+	fmt.Fprintln(h, opts.NoOptimize)
+	fmt.Fprintln(h, strings.Join(opts.AllowPaths, ","))
+
+	for _, file := range sorted {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading %s", file)
+		}
+
+		fmt.Fprintln(h, file)
+		h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,123 @@
+package compiler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheKeyDiffersOnContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compiler-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "Foo.sol")
+	if err := ioutil.WriteFile(file, []byte("contract Foo {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key1, err := cacheKey(Options{}, "0.8.0", []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("contract Foo { uint x; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key2, err := cacheKey(Options{}, "0.8.0", []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1 == key2 {
+		t.Errorf("cacheKey did not change when source content changed")
+	}
+}
+
+func TestCacheKeyDiffersOnOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compiler-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "Foo.sol")
+	if err := ioutil.WriteFile(file, []byte("contract Foo {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key1, err := cacheKey(Options{}, "0.8.0", []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key2, err := cacheKey(Options{NoOptimize: true}, "0.8.0", []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1 == key2 {
+		t.Errorf("cacheKey did not change when NoOptimize changed")
+	}
+}
+
+// TestCopySourcesToTempDirAvoidsBasenameCollision guards against the bug
+// where two files sharing a basename but living in different directories
+// (e.g. vendored copies of the same library) silently overwrote each other
+// once staged into solc's temp working directory.
+func TestCopySourcesToTempDirAvoidsBasenameCollision(t *testing.T) {
+	dirA, err := ioutil.TempDir("", "compiler-test-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirA)
+
+	dirB, err := ioutil.TempDir("", "compiler-test-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirB)
+
+	fileA := filepath.Join(dirA, "SafeMath.sol")
+	fileB := filepath.Join(dirB, "SafeMath.sol")
+	if err := ioutil.WriteFile(fileA, []byte("// version A"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(fileB, []byte("// version B"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srcDir, staged, err := copySourcesToTempDir([]string{fileA, fileB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcDir)
+
+	stagedA, stagedB := staged[fileA], staged[fileB]
+	if stagedA == "" || stagedB == "" {
+		t.Fatalf("copySourcesToTempDir did not stage both files: %v", staged)
+	}
+	if stagedA == stagedB {
+		t.Fatalf("both files staged at the same path %q, one will overwrite the other", stagedA)
+	}
+
+	contentA, err := ioutil.ReadFile(stagedA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentB, err := ioutil.ReadFile(stagedB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(contentA) != "// version A" {
+		t.Errorf("staged copy of fileA has wrong content: %q", contentA)
+	}
+	if string(contentB) != "// version B" {
+		t.Errorf("staged copy of fileB has wrong content: %q", contentB)
+	}
+}
@@ -0,0 +1,54 @@
+package solar
+
+import "sync"
+
+// events is an in-process pub/sub broadcaster: Deployer() and friends send
+// progress on the shared channel built into solarCLI.Reporter(), and every
+// subscriber returned by Subscribe gets its own copy, so `solar serve`'s
+// /events handler can fan that stream out to any number of listeners.
+type events struct {
+	in chan interface{}
+
+	mu   sync.Mutex
+	subs map[chan interface{}]bool
+}
+
+// Start relays everything sent on e.in to every current subscriber. It never
+// returns and is meant to run in its own goroutine, started once by
+// solarCLI.Reporter().
+func (e *events) Start() {
+	for event := range e.in {
+		e.mu.Lock()
+		for sub := range e.subs {
+			sub <- event
+		}
+		e.mu.Unlock()
+	}
+}
+
+// Subscribe registers a new subscriber and returns the channel it receives
+// events on.
+func (e *events) Subscribe() chan interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.subs == nil {
+		e.subs = map[chan interface{}]bool{}
+	}
+
+	sub := make(chan interface{})
+	e.subs[sub] = true
+
+	return sub
+}
+
+// Unsubscribe removes sub, as returned by Subscribe, and closes it.
+func (e *events) Unsubscribe(sub chan interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.subs[sub] {
+		delete(e.subs, sub)
+		close(sub)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !urfave_cli_no_docs
+// +build !urfave_cli_no_docs
+
+package solar
+
+// deployCommandDescription is the long-form help text shown by
+// `solar contract deploy --help`. It is compiled out of release binaries built
+// with `-tags urfave_cli_no_docs` to keep them small; see help_lite.go.
+func deployCommandDescription() string {
+	return `Deploys every contract declared in solar.json that isn't already recorded
+in the contracts repository for the current --env, compiles it with solc and
+submits the deployment transaction(s) through --sicash_rpc/--eth_rpc.
+
+Supplying a comma-separated list of RPC endpoints (with a matching
+--chain_ids list) fans the same deploy out to every chain in parallel and
+records each chain's addresses in its own contracts repository file.`
+}
@@ -0,0 +1,10 @@
+//go:build urfave_cli_no_docs
+// +build urfave_cli_no_docs
+
+package solar
+
+// deployCommandDescription is omitted from urfave_cli_no_docs release builds;
+// see help_full.go.
+func deployCommandDescription() string {
+	return ""
+}
@@ -0,0 +1,143 @@
+// Package keystore wraps go-ethereum's encrypted key store so solar can sign and
+// submit deploy transactions locally (via eth_sendRawTransaction) instead of
+// relying on a remote node's personal_* RPC namespace.
+package keystore
+
+import (
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	gethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// KeyStore manages the set of local scrypt-encrypted accounts solar can sign
+// deploy transactions with, and which account signs which contract's deployment.
+type KeyStore struct {
+	ks *gethkeystore.KeyStore
+
+	mu          sync.Mutex
+	unlocked    []accounts.Account
+	next        int
+	perContract map[string]accounts.Account
+}
+
+// Open opens the encrypted keyfile directory dir, without unlocking any account.
+func Open(dir string) *KeyStore {
+	return &KeyStore{
+		ks: gethkeystore.NewKeyStore(dir, gethkeystore.StandardScryptN, gethkeystore.StandardScryptP),
+	}
+}
+
+// Accounts lists every keyfile found in the keystore directory, locked or not.
+func (k *KeyStore) Accounts() []accounts.Account {
+	return k.ks.Accounts()
+}
+
+// UnlockWithPassphraseFile unlocks every account in the keystore directory with
+// the single passphrase stored in passphraseFile. This is the batch-deployer
+// use case: many keyfiles, one passphrase, round-robin sender selection below.
+func (k *KeyStore) UnlockWithPassphraseFile(passphraseFile string) error {
+	passphrase, err := readPassphrase(passphraseFile)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, account := range k.ks.Accounts() {
+		if err := k.ks.Unlock(account, passphrase); err != nil {
+			return errors.Wrapf(err, "unlocking %s", account.Address.Hex())
+		}
+
+		k.unlocked = append(k.unlocked, account)
+	}
+
+	return nil
+}
+
+// Unlock unlocks a single account by address with its own passphrase.
+func (k *KeyStore) Unlock(address, passphrase string) error {
+	account := accounts.Account{Address: common.HexToAddress(address)}
+	if err := k.ks.Unlock(account, passphrase); err != nil {
+		return errors.Wrapf(err, "unlocking %s", address)
+	}
+
+	k.mu.Lock()
+	k.unlocked = append(k.unlocked, account)
+	k.mu.Unlock()
+
+	return nil
+}
+
+// AssignToContract pins contractName's deployment to a specific unlocked
+// account, overriding round-robin selection for that contract only. It errors
+// if address isn't one of the accounts unlocked so far, so a typo in
+// --keystore_senders is caught at startup instead of deep inside signing.
+func (k *KeyStore) AssignToContract(contractName, address string) error {
+	account := accounts.Account{Address: common.HexToAddress(address)}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var found bool
+	for _, unlocked := range k.unlocked {
+		if unlocked.Address == account.Address {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.Errorf("--keystore_senders: %s is not an unlocked account", address)
+	}
+
+	if k.perContract == nil {
+		k.perContract = map[string]accounts.Account{}
+	}
+
+	k.perContract[contractName] = account
+
+	return nil
+}
+
+// SenderFor returns the account that should sign contractName's deployment: the
+// account pinned with AssignToContract if any, otherwise the next unlocked
+// account in round-robin order.
+func (k *KeyStore) SenderFor(contractName string) (accounts.Account, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if account, pinned := k.perContract[contractName]; pinned {
+		return account, nil
+	}
+
+	if len(k.unlocked) == 0 {
+		return accounts.Account{}, errors.New("keystore: no unlocked accounts")
+	}
+
+	account := k.unlocked[k.next%len(k.unlocked)]
+	k.next++
+
+	return account, nil
+}
+
+// SignTx signs tx with account's key, so the caller can submit it raw via
+// eth_sendRawTransaction rather than asking a remote node to sign it.
+func (k *KeyStore) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return k.ks.SignTx(account, tx, chainID)
+}
+
+func readPassphrase(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading passphrase file %s", path)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
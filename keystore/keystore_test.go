@@ -0,0 +1,75 @@
+package keystore
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func addr(hex string) accounts.Account {
+	return accounts.Account{Address: common.HexToAddress(hex)}
+}
+
+func TestSenderForRoundRobin(t *testing.T) {
+	a, b := addr("0x1"), addr("0x2")
+	k := &KeyStore{unlocked: []accounts.Account{a, b}}
+
+	var got []accounts.Account
+	for i := 0; i < 4; i++ {
+		sender, err := k.SenderFor("SomeContract")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, sender)
+	}
+
+	want := []accounts.Account{a, b, a, b}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("round %d: got %s, want %s", i, got[i].Address.Hex(), want[i].Address.Hex())
+		}
+	}
+}
+
+func TestSenderForNoUnlockedAccounts(t *testing.T) {
+	k := &KeyStore{}
+
+	if _, err := k.SenderFor("SomeContract"); err == nil {
+		t.Error("expected an error with no unlocked accounts, got nil")
+	}
+}
+
+func TestAssignToContractPinsOverRoundRobin(t *testing.T) {
+	a, b := addr("0x1"), addr("0x2")
+	k := &KeyStore{unlocked: []accounts.Account{a, b}}
+
+	if err := k.AssignToContract("Pinned", "0x2"); err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := k.SenderFor("Pinned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != b {
+		t.Errorf("AssignToContract did not pin the sender: got %s, want %s", sender.Address.Hex(), b.Address.Hex())
+	}
+
+	// An unrelated contract still round-robins, unaffected by the pin.
+	other, err := k.SenderFor("Unpinned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other != a {
+		t.Errorf("pinning Pinned affected round-robin for Unpinned: got %s, want %s", other.Address.Hex(), a.Address.Hex())
+	}
+}
+
+func TestAssignToContractRejectsUnknownAddress(t *testing.T) {
+	k := &KeyStore{unlocked: []accounts.Account{addr("0x1")}}
+
+	if err := k.AssignToContract("SomeContract", "0xdeadbeef"); err == nil {
+		t.Error("expected an error pinning an address that was never unlocked, got nil")
+	}
+}
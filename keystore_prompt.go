@@ -0,0 +1,32 @@
+package solar
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/sicashproject/solar/keystore"
+)
+
+// promptUnlockKeystore unlocks every account in ks by prompting for its
+// passphrase on the terminal, one key at a time. It's the interactive
+// counterpart to --keystore_passphrase_file, for operators who'd rather not
+// put a single shared passphrase in a file.
+func promptUnlockKeystore(ks *keystore.KeyStore) error {
+	for _, account := range ks.Accounts() {
+		fmt.Fprintf(os.Stderr, "Passphrase for %s: ", account.Address.Hex())
+
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		if err := ks.Unlock(account.Address.Hex(), string(passphrase)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
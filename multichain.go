@@ -0,0 +1,70 @@
+package solar
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sicashproject/solar/deployer"
+)
+
+// chainTarget pairs a single-chain deployer with the chain id it was built for, so a
+// multiDeployer can tag progress and results by chain.
+type chainTarget struct {
+	chainID  string
+	deployer deployer.Deployer
+}
+
+// multiDeployer fans a single `solar deploy` invocation out to every configured
+// endpoint in parallel. It implements deployer.Deployer so it is a drop-in
+// replacement anywhere solarCLI.Deployer() is used for a single chain.
+type multiDeployer struct {
+	targets []chainTarget
+}
+
+// chainDeployResult carries the outcome of deploying to a single chain so the
+// other chains are never blocked or aborted by one chain's failure.
+type chainDeployResult struct {
+	chainID string
+	err     error
+}
+
+// Deploy runs Deploy on every configured chain concurrently and waits for all of
+// them to finish. Failures are reported per chain; a failure on one chain never
+// aborts the others.
+func (m *multiDeployer) Deploy() error {
+	var wg sync.WaitGroup
+	results := make(chan chainDeployResult, len(m.targets))
+
+	for _, target := range m.targets {
+		wg.Add(1)
+		go func(target chainTarget) {
+			defer wg.Done()
+			results <- chainDeployResult{
+				chainID: target.chainID,
+				err:     target.deployer.Deploy(),
+			}
+		}(target)
+	}
+
+	wg.Wait()
+	close(results)
+
+	var failedChains []string
+	for result := range results {
+		if result.err != nil {
+			fmt.Printf("chain %s: deploy failed: %s\n", result.chainID, result.err)
+			failedChains = append(failedChains, result.chainID)
+			continue
+		}
+
+		fmt.Printf("chain %s: deploy succeeded\n", result.chainID)
+	}
+
+	if len(failedChains) > 0 {
+		return errors.Errorf("deploy failed on chain(s): %s", strings.Join(failedChains, ", "))
+	}
+
+	return nil
+}
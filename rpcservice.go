@@ -0,0 +1,285 @@
+package solar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// jsonrpcRequest is a single JSON-RPC 2.0 request, as sent by CI systems and other
+// services driving solar programmatically.
+type jsonrpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type jsonrpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcService exposes solarCLI over JSON-RPC 2.0: solar_deploy, solar_status,
+// solar_predictAddress, solar_listContracts and solar_confirm. It is what `solar
+// serve` binds to one or more listen addresses.
+type rpcService struct {
+	cli *solarCLI
+}
+
+func newRPCService(cli *solarCLI) *rpcService {
+	return &rpcService{cli: cli}
+}
+
+func (s *rpcService) handle(req jsonrpcRequest) jsonrpcResponse {
+	resp := jsonrpcResponse{Version: "2.0", ID: req.ID}
+
+	var (
+		result interface{}
+		err    error
+	)
+
+	switch req.Method {
+	case "solar_deploy":
+		err = s.cli.Deployer().Deploy()
+		result = err == nil
+	case "solar_status":
+		result = s.status()
+	case "solar_predictAddress":
+		result, err = s.predictAddress(req.Params)
+	case "solar_listContracts":
+		result = s.listContracts()
+	case "solar_confirm":
+		result, err = s.confirm(req.Params)
+	default:
+		err = errors.Errorf("method not found: %s", req.Method)
+	}
+
+	if err != nil {
+		resp.Error = &jsonrpcError{Code: -32000, Message: err.Error()}
+		return resp
+	}
+
+	resp.Result = result
+	return resp
+}
+
+func (s *rpcService) status() map[string]interface{} {
+	return map[string]interface{}{
+		"env": s.cli.solarEnv,
+	}
+}
+
+func (s *rpcService) listContracts() []string {
+	repo := s.cli.ContractsRepository()
+
+	var names []string
+	for name := range repo.ListAll() {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+type predictAddressParams struct {
+	Name string `json:"name"`
+}
+
+func (s *rpcService) predictAddress(raw json.RawMessage) (interface{}, error) {
+	var params predictAddressParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, errors.Wrap(err, "solar_predictAddress params")
+	}
+
+	contract, found := s.cli.ContractsRepository().Get(params.Name)
+	if !found {
+		return nil, errors.Errorf("unknown contract: %s", params.Name)
+	}
+
+	return contract.Address.String(), nil
+}
+
+type confirmParams struct {
+	Name string `json:"name"`
+	TxID string `json:"txID"`
+}
+
+type transactionReceipt struct {
+	Status string `json:"status"`
+}
+
+type getTransactionReceiptResponse struct {
+	Result *transactionReceipt `json:"result"`
+	Error  *jsonrpcError       `json:"error"`
+}
+
+// confirm reports whether txID, the transaction that deployed name, has been
+// mined and succeeded. It looks the contract up to make sure it's one solar
+// actually knows about, then asks the chain for the transaction's own receipt
+// rather than trusting the caller's say-so.
+func (s *rpcService) confirm(raw json.RawMessage) (interface{}, error) {
+	var params confirmParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, errors.Wrap(err, "solar_confirm params")
+	}
+
+	if _, found := s.cli.ContractsRepository().Get(params.Name); !found {
+		return nil, errors.Errorf("unknown contract: %s", params.Name)
+	}
+
+	rpcURL, err := s.cli.endpointForChain("")
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := fetchTransactionReceipt(rpcURL, params.TxID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching transaction receipt")
+	}
+
+	return receipt != nil && receipt.Status == "0x1", nil
+}
+
+func fetchTransactionReceipt(rpcURL, txHash string) (*transactionReceipt, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_getTransactionReceipt",
+		"params":  []string{txHash},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post(rpcURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var decoded getTransactionReceiptResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	if decoded.Error != nil {
+		return nil, errors.New(decoded.Error.Message)
+	}
+
+	return decoded.Result, nil
+}
+
+func (s *rpcService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/events" {
+		s.serveEvents(w, r)
+		return
+	}
+
+	var req jsonrpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.handle(req))
+}
+
+// serveEvents upgrades the request to a WebSocket connection and subscribes it
+// to solarCLI's Reporter() event stream, pushing each event as its own JSON
+// text frame, so CI systems and other services can subscribe instead of
+// polling solar_status.
+func (s *rpcService) serveEvents(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer ws.Close()
+
+	reporter := s.cli.Reporter()
+	sub := reporter.Subscribe()
+	defer reporter.Unsubscribe(sub)
+
+	for event := range sub {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+
+		if err := ws.WriteText(payload); err != nil {
+			return
+		}
+	}
+}
+
+// Serve binds the JSON-RPC service to every address in addresses and blocks
+// until one of them fails, closing every listener before returning so the
+// others stop serving too instead of leaking. Each address may be a
+// `host:port` pair (TCP) or a unix socket path (one starting with "/" or
+// ending in ".sock"), so operators can expose the service on several TCP ports
+// and unix sockets at the same time. Every listener serves plain HTTP; TLS is
+// not terminated here.
+func (s *rpcService) Serve(addresses []string) error {
+	if len(addresses) == 0 {
+		return errors.New("rpc service: no listen addresses given")
+	}
+
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}
+
+	for _, address := range addresses {
+		listener, err := listen(address)
+		if err != nil {
+			closeAll()
+			return errors.Wrapf(err, "listening on %s", address)
+		}
+
+		fmt.Printf("solar serve: listening on %s\n", address)
+		listeners = append(listeners, listener)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(listeners))
+
+	for _, listener := range listeners {
+		wg.Add(1)
+		go func(listener net.Listener) {
+			defer wg.Done()
+			errs <- http.Serve(listener, s)
+		}(listener)
+	}
+
+	// Closing every listener the moment one fails makes the other http.Serve
+	// calls return promptly too, instead of wg.Wait() blocking forever on
+	// listeners that are still happily accepting connections.
+	err := <-errs
+	closeAll()
+	wg.Wait()
+	return err
+}
+
+func listen(address string) (net.Listener, error) {
+	if strings.HasPrefix(address, "/") || strings.HasSuffix(address, ".sock") {
+		return net.Listen("unix", address)
+	}
+
+	return net.Listen("tcp", address)
+}
@@ -0,0 +1,178 @@
+package solar
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sicashproject/solar/compiler"
+)
+
+// VerifyResult is the outcome of comparing a contract's locally compiled
+// deployed bytecode against what's actually on chain.
+type VerifyResult struct {
+	Name     string
+	Match    bool
+	Expected string
+	Actual   string
+}
+
+// Verify re-fetches name's deployed bytecode over RPC and compares it, with the
+// solc metadata hash stripped from both sides, against a local build compiled
+// fresh from sourceFiles via the compiler package. chainID selects which chain
+// to verify against when solar was configured with multiple RPC endpoints
+// (chunk0-1); pass "" when only one endpoint is configured.
+//
+// Verify compiles sourceFiles itself rather than trusting a deployed bytecode
+// already sitting on the contracts repository entry, because nothing in this
+// repository's deploy path persists compiler.Compile's output there: the
+// contract and deployer packages record no compiled bytecode — comparing
+// against an unpopulated field would always "match" and tell the caller
+// nothing.
+func (c *solarCLI) Verify(name, chainID string, sourceFiles []string) (*VerifyResult, error) {
+	if len(sourceFiles) == 0 {
+		return nil, errors.New("--source is required: verify compiles its own local baseline, since the contracts repository doesn't record compiled bytecode yet")
+	}
+
+	rpcURL, err := c.endpointForChain(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, found := c.ContractsRepositoryForChain(chainID).Get(name)
+	if !found {
+		return nil, errors.Errorf("unknown contract: %s", name)
+	}
+
+	opts, err := c.SolcOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := compiler.Compile(*opts, sourceFiles...)
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling local baseline")
+	}
+
+	built, err := contractByName(compiled, name)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := fetchCode(rpcURL, entry.Address.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching deployed code")
+	}
+
+	expected := stripMetadataHash(built.DeployedBin)
+	actual = stripMetadataHash(actual)
+
+	return &VerifyResult{
+		Name:     name,
+		Match:    expected == actual,
+		Expected: expected,
+		Actual:   actual,
+	}, nil
+}
+
+// contractByName finds the single contract named name among compiled, which
+// solc keys by "path/to/File.sol:Name" rather than by bare name.
+func contractByName(compiled map[string]*compiler.Contract, name string) (*compiler.Contract, error) {
+	for key, built := range compiled {
+		if key == name || strings.HasSuffix(key, ":"+name) {
+			return built, nil
+		}
+	}
+
+	return nil, errors.Errorf("%s: not found among compiled sources", name)
+}
+
+// endpointForChain picks the single RPC endpoint that matches chainID out of
+// the (possibly several) endpoints configured via --sicash_rpc/--eth_rpc and
+// --chain_ids. With exactly one endpoint configured, chainID may be left empty.
+func (c *solarCLI) endpointForChain(chainID string) (string, error) {
+	sicashURLs := splitEndpoints(c.sicashRPC)
+	ethURLs := splitEndpoints(c.ethRPC)
+
+	if len(sicashURLs) == 0 && len(ethURLs) == 0 {
+		return "", errorUnspecifiedRPC
+	}
+
+	all := append(append([]string{}, sicashURLs...), ethURLs...)
+
+	if chainID == "" {
+		if len(all) == 1 {
+			return all[0], nil
+		}
+
+		return "", errors.New("multiple RPC endpoints configured; pass --chain_id to pick which one to verify against")
+	}
+
+	ids := splitEndpoints(c.chainIDs)
+	for i, endpoint := range all {
+		if i < len(ids) && ids[i] == chainID {
+			return endpoint, nil
+		}
+	}
+
+	return "", errors.Errorf("no RPC endpoint configured for chain id %q", chainID)
+}
+
+type ethGetCodeResponse struct {
+	Result string        `json:"result"`
+	Error  *jsonrpcError `json:"error"`
+}
+
+// fetchCode calls eth_getCode (SICash is EVM-compatible and speaks the same RPC
+// method) to fetch the bytecode currently deployed at address.
+func fetchCode(rpcURL, address string) (string, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_getCode",
+		"params":  []string{address, "latest"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(rpcURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var decoded ethGetCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return "", err
+	}
+
+	if decoded.Error != nil {
+		return "", errors.New(decoded.Error.Message)
+	}
+
+	return decoded.Result, nil
+}
+
+// stripMetadataHash removes the CBOR-encoded swarm/ipfs metadata hash solc
+// appends to the end of compiled bytecode, so two builds that only differ by
+// that hash (e.g. a different absolute source path) still compare equal.
+func stripMetadataHash(bytecodeHex string) string {
+	bytecodeHex = strings.TrimPrefix(bytecodeHex, "0x")
+
+	raw, err := hex.DecodeString(bytecodeHex)
+	if err != nil || len(raw) < 2 {
+		return bytecodeHex
+	}
+
+	cborLen := int(raw[len(raw)-2])<<8 | int(raw[len(raw)-1])
+	cutoff := len(raw) - 2 - cborLen
+	if cutoff <= 0 || cutoff > len(raw) {
+		return bytecodeHex
+	}
+
+	return hex.EncodeToString(raw[:cutoff])
+}
@@ -0,0 +1,32 @@
+package solar
+
+import "testing"
+
+func TestStripMetadataHash(t *testing.T) {
+	// 3 bytes of code (aabbcc), followed by a 2-byte CBOR payload and its 2-byte big-endian
+	// length, mirrors the tail solc actually appends to deployed bytecode.
+	code := "aabbcc"
+	cbor := "dead"
+	length := "0002"
+	bytecode := code + cbor + length
+
+	got := stripMetadataHash(bytecode)
+	if got != code {
+		t.Errorf("stripMetadataHash(%q) = %q, want %q", bytecode, got, code)
+	}
+}
+
+func TestStripMetadataHashWithPrefix(t *testing.T) {
+	got := stripMetadataHash("0xaabbccdead0002")
+	if got != "aabbcc" {
+		t.Errorf("stripMetadataHash with 0x prefix = %q, want %q", got, "aabbcc")
+	}
+}
+
+func TestStripMetadataHashMalformed(t *testing.T) {
+	for _, bytecode := range []string{"", "a", "zz", "aa"} {
+		if got := stripMetadataHash(bytecode); got != bytecode {
+			t.Errorf("stripMetadataHash(%q) = %q, want unchanged input", bytecode, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package solar
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// websocketAcceptGUID is the fixed GUID RFC6455 has servers concatenate onto
+// Sec-WebSocket-Key before hashing, to prove the handshake was understood.
+const websocketAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsConn is a minimal, server-push-only RFC6455 WebSocket connection: just
+// enough to frame outbound text messages for the solar serve event
+// subscription, without pulling in a full client/extension-aware websocket
+// library.
+type wsConn struct {
+	conn net.Conn
+}
+
+// upgradeWebSocket performs the RFC6455 handshake and hijacks the underlying
+// TCP connection so the caller can push frames directly to it.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("not a websocket upgrade request")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("websocket upgrade unsupported by this connection")
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n"
+
+	if _, err := buf.WriteString(handshake); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := buf.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &wsConn{conn: conn}, nil
+}
+
+func websocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	h.Write([]byte(websocketAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// WriteText sends data as a single, unmasked, unfragmented WebSocket text frame
+// (opcode 0x1), as RFC6455 requires server-to-client frames to be.
+func (w *wsConn) WriteText(data []byte) error {
+	var header []byte
+
+	switch {
+	case len(data) <= 125:
+		header = []byte{0x81, byte(len(data))}
+	case len(data) <= 65535:
+		header = make([]byte, 4)
+		header[0] = 0x81
+		header[1] = 126
+		binary.BigEndian.PutUint16(header[2:], uint16(len(data)))
+	default:
+		header = make([]byte, 10)
+		header[0] = 0x81
+		header[1] = 127
+		binary.BigEndian.PutUint64(header[2:], uint64(len(data)))
+	}
+
+	if _, err := w.conn.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.conn.Write(data)
+	return err
+}
+
+func (w *wsConn) Close() error {
+	return w.conn.Close()
+}